@@ -0,0 +1,123 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	binaryMagic   = "GOQ1"
+	binaryVersion = uint32(1)
+)
+
+// binaryHeader precedes the gob-encoded elements written by WriteTo. It
+// carries the element count but deliberately not q's internal power-of-two
+// capacity, so a queue serialized at one capacity can be restored into a
+// fresh queue that chooses its own capacity via lazyGrow.
+type binaryHeader struct {
+	Magic   [4]byte
+	Version uint32
+	Count   uint64
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WriteTo writes the header followed by every element of queue q, gob-
+// encoded front to back, to w. If T is an encoding.BinaryMarshaler, or has
+// been registered with gob.Register as a concrete type behind an
+// interface, gob uses that to encode it; see the encoding/gob docs. It
+// implements io.WriterTo.
+func (q *Queue[T]) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	var header binaryHeader
+	copy(header.Magic[:], binaryMagic)
+	header.Version = binaryVersion
+	header.Count = uint64(q.length)
+	if err := binary.Write(cw, binary.BigEndian, header); err != nil {
+		return cw.n, err
+	}
+	enc := gob.NewEncoder(cw)
+	j := q.front
+	for i := 0; i < q.length; i++ {
+		if err := enc.Encode(q.rep[j].Value); err != nil {
+			return cw.n, err
+		}
+		j = q.inc(j)
+	}
+	return cw.n, nil
+}
+
+// ReadFrom replaces the contents of queue q with the elements read from a
+// stream previously written by WriteTo. It implements io.ReaderFrom. If q
+// is bounded (via NewBounded/SetMaxLen) and the stream holds more elements
+// than q's maxLen under the DropNewest/ReturnError policy, ReadFrom stops
+// and returns an error rather than silently truncating the restored data.
+func (q *Queue[T]) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	var header binaryHeader
+	if err := binary.Read(cr, binary.BigEndian, &header); err != nil {
+		return cr.n, err
+	}
+	if string(header.Magic[:]) != binaryMagic {
+		return cr.n, errors.New("queue: bad magic in binary stream")
+	}
+	if header.Version != binaryVersion {
+		return cr.n, fmt.Errorf("queue: unsupported binary version %d", header.Version)
+	}
+	q.Init()
+	dec := gob.NewDecoder(cr)
+	for i := uint64(0); i < header.Count; i++ {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return cr.n, err
+		}
+		if !q.PushBack(v) {
+			return cr.n, fmt.Errorf("queue: ReadFrom: element %d of %d rejected, queue is bounded to %d elements", i, header.Count, q.maxLen)
+		}
+	}
+	return cr.n, nil
+}
+
+// MarshalBinary encodes queue q using the same format as WriteTo.
+func (q *Queue[T]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := q.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the contents of queue q by decoding data
+// written by MarshalBinary or WriteTo.
+func (q *Queue[T]) UnmarshalBinary(data []byte) error {
+	_, err := q.ReadFrom(bytes.NewReader(data))
+	return err
+}
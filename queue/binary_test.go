@@ -0,0 +1,97 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestQueueBinaryRoundTrip(t *testing.T) {
+	src := New[int]()
+	for i := 0; i < 37; i++ {
+		src.PushBack(i)
+	}
+	// Drop from the front and push more so front != 0 in rep.
+	for i := 0; i < 5; i++ {
+		src.PopFront()
+	}
+	for i := 37; i < 40; i++ {
+		src.PushBack(i)
+	}
+
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	dst := New[int]()
+	if err := dst.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if dst.Len() != src.Len() {
+		t.Fatalf("Len = %d, want %d", dst.Len(), src.Len())
+	}
+	for i := 5; i < 40; i++ {
+		v, ok := dst.PopFront()
+		if !ok || v != i {
+			t.Fatalf("PopFront = %v, %v; want %d", v, ok, i)
+		}
+	}
+}
+
+func TestQueueWriteToReadFromCapacityIndependent(t *testing.T) {
+	src := New[int]()
+	for i := 0; i < 1024; i++ {
+		src.PushBack(i)
+	}
+
+	var buf bytes.Buffer
+	n, err := src.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("WriteTo returned %d, but buffer holds %d bytes", n, buf.Len())
+	}
+
+	// A fresh queue starts from its own initial capacity and grows via
+	// lazyGrow; the stream carries no trace of src's internal ring size.
+	dst := New[int]()
+	if _, err := dst.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if dst.Len() != 1024 {
+		t.Fatalf("Len = %d, want 1024", dst.Len())
+	}
+	for i := 0; i < 1024; i++ {
+		if v, ok := dst.PopFront(); !ok || v != i {
+			t.Fatalf("PopFront = %v, %v; want %d", v, ok, i)
+		}
+	}
+}
+
+func TestQueueReadFromRejectsBadMagic(t *testing.T) {
+	dst := New[int]()
+	if _, err := dst.ReadFrom(bytes.NewReader([]byte("not a queue stream"))); err == nil {
+		t.Fatalf("ReadFrom should reject a stream with a bad header")
+	}
+}
+
+func TestQueueReadFromRespectsBound(t *testing.T) {
+	src := New[int]()
+	for i := 0; i < 10; i++ {
+		src.PushBack(i)
+	}
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	dst := NewBounded[int](3, DropNewest)
+	if err := dst.UnmarshalBinary(data); err == nil {
+		t.Fatalf("UnmarshalBinary should error instead of silently truncating past maxLen")
+	}
+}
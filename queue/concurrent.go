@@ -0,0 +1,175 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// ConcurrentQueue wraps a Queue[T] with a mutex and condition variable so
+// it is safe for concurrent use, unlike Queue[T] itself. PushFrontWait/
+// PushBackWait and PopFrontWait/PopBackWait additionally block, with
+// context-aware cancellation, the way a buffered channel's send and
+// receive would, but without a channel's per-element allocation.
+type ConcurrentQueue[T any] struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	q    *Queue[T]
+}
+
+// NewConcurrent returns an initialized empty concurrent queue.
+func NewConcurrent[T any]() *ConcurrentQueue[T] {
+	cq := &ConcurrentQueue[T]{q: New[T]()}
+	cq.cond = sync.NewCond(&cq.mu)
+	return cq
+}
+
+// NewConcurrentBounded returns an initialized empty concurrent queue that
+// holds at most max elements, applying policy the same way NewBounded
+// does. PushFrontWait/PushBackWait block producers while the queue is
+// full regardless of policy; use PushFront/PushBack for the non-blocking
+// policy-governed behavior.
+func NewConcurrentBounded[T any](max int, policy OverflowPolicy) *ConcurrentQueue[T] {
+	cq := &ConcurrentQueue[T]{q: NewBounded[T](max, policy)}
+	cq.cond = sync.NewCond(&cq.mu)
+	return cq
+}
+
+// broadcastLocked acquires cq.mu before calling cq.cond.Broadcast. It is
+// used as the context.AfterFunc callback in the *Wait methods below: the
+// callback runs on its own goroutine, so without taking the lock first it
+// could race a waiter between its ctx.Err() check and the subsequent
+// cq.cond.Wait() call, firing the broadcast in that gap where neither the
+// predicate recheck nor Wait's own registration would observe it and
+// silently missing the cancellation.
+func (cq *ConcurrentQueue[T]) broadcastLocked() {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	cq.cond.Broadcast()
+}
+
+// Len returns the number of elements currently in the queue.
+func (cq *ConcurrentQueue[T]) Len() int {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	return cq.q.Len()
+}
+
+// PushFront inserts v at the front of the queue. See Queue.PushFront for
+// the return value's meaning under a bounded queue's overflow policy.
+func (cq *ConcurrentQueue[T]) PushFront(v T) bool {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	ok := cq.q.PushFront(v)
+	if ok {
+		cq.cond.Broadcast()
+	}
+	return ok
+}
+
+// PushBack inserts v at the back of the queue. See Queue.PushBack for the
+// return value's meaning under a bounded queue's overflow policy.
+func (cq *ConcurrentQueue[T]) PushBack(v T) bool {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	ok := cq.q.PushBack(v)
+	if ok {
+		cq.cond.Broadcast()
+	}
+	return ok
+}
+
+// PopFront removes and returns the first element of the queue, or reports
+// false if it was empty.
+func (cq *ConcurrentQueue[T]) PopFront() (T, bool) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	v, ok := cq.q.PopFront()
+	if ok {
+		cq.cond.Broadcast()
+	}
+	return v, ok
+}
+
+// PopBack removes and returns the last element of the queue, or reports
+// false if it was empty.
+func (cq *ConcurrentQueue[T]) PopBack() (T, bool) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	v, ok := cq.q.PopBack()
+	if ok {
+		cq.cond.Broadcast()
+	}
+	return v, ok
+}
+
+// PushFrontWait inserts v at the front of the queue, blocking while a
+// bounded queue is full until room frees up or ctx is done.
+func (cq *ConcurrentQueue[T]) PushFrontWait(ctx context.Context, v T) error {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	defer context.AfterFunc(ctx, cq.broadcastLocked)()
+	for cq.q.boundedFull() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cq.cond.Wait()
+	}
+	cq.q.PushFront(v)
+	cq.cond.Broadcast()
+	return nil
+}
+
+// PushBackWait inserts v at the back of the queue, blocking while a
+// bounded queue is full until room frees up or ctx is done.
+func (cq *ConcurrentQueue[T]) PushBackWait(ctx context.Context, v T) error {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	defer context.AfterFunc(ctx, cq.broadcastLocked)()
+	for cq.q.boundedFull() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		cq.cond.Wait()
+	}
+	cq.q.PushBack(v)
+	cq.cond.Broadcast()
+	return nil
+}
+
+// PopFrontWait removes and returns the first element of the queue,
+// blocking until one arrives or ctx is done.
+func (cq *ConcurrentQueue[T]) PopFrontWait(ctx context.Context) (T, error) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	defer context.AfterFunc(ctx, cq.broadcastLocked)()
+	for cq.q.empty() {
+		if err := ctx.Err(); err != nil {
+			return *new(T), err
+		}
+		cq.cond.Wait()
+	}
+	v, _ := cq.q.PopFront()
+	cq.cond.Broadcast()
+	return v, nil
+}
+
+// PopBackWait removes and returns the last element of the queue, blocking
+// until one arrives or ctx is done.
+func (cq *ConcurrentQueue[T]) PopBackWait(ctx context.Context) (T, error) {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	defer context.AfterFunc(ctx, cq.broadcastLocked)()
+	for cq.q.empty() {
+		if err := ctx.Err(); err != nil {
+			return *new(T), err
+		}
+		cq.cond.Wait()
+	}
+	v, _ := cq.q.PopBack()
+	cq.cond.Broadcast()
+	return v, nil
+}
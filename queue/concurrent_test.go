@@ -0,0 +1,123 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentQueuePushPop(t *testing.T) {
+	cq := NewConcurrent[int]()
+	if !cq.PushBack(1) {
+		t.Fatalf("PushBack should succeed on an unbounded queue")
+	}
+	cq.PushFront(0)
+	cq.PushBack(2)
+	for _, want := range []int{0, 1, 2} {
+		if v, ok := cq.PopFront(); !ok || v != want {
+			t.Fatalf("PopFront = %v, %v; want %d", v, ok, want)
+		}
+	}
+	if _, ok := cq.PopFront(); ok {
+		t.Fatalf("PopFront on empty queue should report false")
+	}
+}
+
+func TestConcurrentQueuePopFrontWaitDelivers(t *testing.T) {
+	cq := NewConcurrent[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cq.PushBack(42)
+	}()
+
+	v, err := cq.PopFrontWait(ctx)
+	if err != nil {
+		t.Fatalf("PopFrontWait returned error: %v", err)
+	}
+	if v != 42 {
+		t.Fatalf("PopFrontWait = %d, want 42", v)
+	}
+}
+
+func TestConcurrentQueuePopFrontWaitCancel(t *testing.T) {
+	cq := NewConcurrent[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := cq.PopFrontWait(ctx); err == nil {
+		t.Fatalf("PopFrontWait on a queue that never fills should return a context error")
+	}
+}
+
+func TestConcurrentQueuePushBackWaitBlocksUntilSpace(t *testing.T) {
+	cq := NewConcurrentBounded[int](1, DropNewest)
+	cq.PushBack(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		if err := cq.PushBackWait(ctx, 2); err != nil {
+			t.Errorf("PushBackWait returned error: %v", err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("PushBackWait returned before the queue had room")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if v, ok := cq.PopFront(); !ok || v != 1 {
+		t.Fatalf("PopFront = %v, %v; want 1", v, ok)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("PushBackWait did not unblock after room freed up")
+	}
+
+	if v, ok := cq.PopFront(); !ok || v != 2 {
+		t.Fatalf("PopFront = %v, %v; want 2", v, ok)
+	}
+}
+
+func TestConcurrentQueueRace(t *testing.T) {
+	cq := NewConcurrent[int]()
+	const n = 200
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			cq.PushBack(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		ctx := context.Background()
+		for i := 0; i < n; i++ {
+			if _, err := cq.PopFrontWait(ctx); err != nil {
+				t.Errorf("PopFrontWait: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if cq.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", cq.Len())
+	}
+}
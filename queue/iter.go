@@ -0,0 +1,65 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import "iter"
+
+// All returns an iterator over the (logical index, value) pairs of queue q,
+// walking front to back. Mutating q during iteration invalidates the
+// iterator: the safe pattern is to finish ranging before pushing, popping,
+// inserting into, or removing from q again.
+func (q *Queue[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		j := q.front
+		for i := 0; i < q.length; i++ {
+			if !yield(i, q.rep[j].Value) {
+				return
+			}
+			j = q.inc(j)
+		}
+	}
+}
+
+// Backward returns an iterator over the (logical index, value) pairs of
+// queue q, walking back to front. Mutating q during iteration invalidates
+// the iterator: the safe pattern is to finish ranging before pushing,
+// popping, inserting into, or removing from q again.
+func (q *Queue[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		j := q.dec(q.back)
+		for i := q.length - 1; i >= 0; i-- {
+			if !yield(i, q.rep[j].Value) {
+				return
+			}
+			j = q.dec(j)
+		}
+	}
+}
+
+// Range calls f for each element of queue q in front-to-back order,
+// stopping early if f returns false. Mutating q during iteration
+// invalidates the iterator.
+func (q *Queue[T]) Range(f func(i int, v T) bool) {
+	j := q.front
+	for i := 0; i < q.length; i++ {
+		if !f(i, q.rep[j].Value) {
+			return
+		}
+		j = q.inc(j)
+	}
+}
+
+// RangeReverse calls f for each element of queue q in back-to-front order,
+// stopping early if f returns false. Mutating q during iteration
+// invalidates the iterator.
+func (q *Queue[T]) RangeReverse(f func(i int, v T) bool) {
+	j := q.dec(q.back)
+	for i := q.length - 1; i >= 0; i-- {
+		if !f(i, q.rep[j].Value) {
+			return
+		}
+		j = q.dec(j)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+// pqEntry is a slot in a PriorityQueue's ring. tomb marks a slot whose
+// value has already been consumed: by PopHighest, in which case the slot
+// stays inside the front..back window until a FIFO pop or a resize
+// reclaims it, or by PopFront/PopBack, which set it on the slot they pop
+// so that a heap entry still pointing there is seen as stale. It is
+// analogous to the ValueSet:false sentinel Queue[T] uses.
+type pqEntry[T any] struct {
+	Value    T
+	Priority int
+	seq      uint64 // insertion order, used to break Priority ties FIFO
+	tomb     bool
+}
+
+// PriorityQueue is a deque that, alongside the usual FIFO PushBack/
+// PushFront and PopFront/PopBack, supports popping by highest priority
+// via PushWithPriority/PopHighest. It keeps a ring for FIFO order exactly
+// like Queue[T], plus a parallel max-heap of indices into that ring for
+// priority order. length counts only live (non-tombstoned) entries;
+// occupied additionally counts tombstoned slots still inside the
+// front..back window, which is what governs resizing.
+type PriorityQueue[T any] struct {
+	rep      []pqEntry[T]
+	front    int
+	back     int
+	length   int
+	occupied int
+	heap     []pqHeapEntry // ordered as a max-heap by (priority, seq)
+	nextSeq  uint64        // monotonic counter handed out to each pushed entry
+}
+
+// pqHeapEntry is a heap node. It carries its own priority and seq rather
+// than reading them out of rep[idx], so a slot reused by a later push (its
+// tomb flipped back to false, with a new Priority/seq of its own) can never
+// be confused with the stale entry: PopHighest treats a heap entry as
+// current only while rep[idx].seq still equals the seq recorded here.
+type pqHeapEntry struct {
+	idx      int
+	priority int
+	seq      uint64
+}
+
+// NewPriorityQueue returns an initialized empty priority queue.
+func NewPriorityQueue[T any]() *PriorityQueue[T] {
+	return new(PriorityQueue[T]).init()
+}
+
+func (q *PriorityQueue[T]) init() *PriorityQueue[T] {
+	q.rep = make([]pqEntry[T], 1)
+	q.front, q.back, q.length, q.occupied = 0, 0, 0, 0
+	q.heap = nil
+	// Start at 1: seq 0 is what every cleared slot holds, so no heap entry
+	// may carry it or a stale entry could match a cleared slot.
+	q.nextSeq = 1
+	return q
+}
+
+func (q *PriorityQueue[T]) lazyInit() {
+	if q.rep == nil {
+		q.init()
+	}
+}
+
+// Len returns the number of live elements of queue q.
+func (q *PriorityQueue[T]) Len() int {
+	return q.length
+}
+
+func (q *PriorityQueue[T]) inc(i int) int {
+	return (i + 1) & (len(q.rep) - 1) // requires l = 2^n
+}
+
+func (q *PriorityQueue[T]) dec(i int) int {
+	return (i - 1) & (len(q.rep) - 1) // requires l = 2^n
+}
+
+func (q *PriorityQueue[T]) full() bool {
+	return q.occupied == len(q.rep)
+}
+
+func (q *PriorityQueue[T]) sparse() bool {
+	return 1 < q.occupied && q.occupied < len(q.rep)/4
+}
+
+func (q *PriorityQueue[T]) lazyGrow() {
+	if q.full() {
+		q.resize(len(q.rep) * 2)
+	}
+}
+
+func (q *PriorityQueue[T]) lazyShrink() {
+	if q.sparse() {
+		q.resize(len(q.rep) / 2)
+	}
+}
+
+// resize rebuilds rep at the given size, dropping tombstones in the
+// process, then rebuilds the heap since every live entry's index changes.
+func (q *PriorityQueue[T]) resize(size int) {
+	oldRep := q.rep
+	oldMask := len(oldRep) - 1
+	adjusted := make([]pqEntry[T], size)
+	n := 0
+	j := q.front
+	for i := 0; i < q.occupied; i++ {
+		if !oldRep[j].tomb {
+			adjusted[n] = oldRep[j]
+			n++
+		}
+		j = (j + 1) & oldMask
+	}
+	q.rep = adjusted
+	q.front = 0
+	q.back = n
+	q.length = n
+	q.occupied = n
+	q.rebuildHeap()
+}
+
+func (q *PriorityQueue[T]) rebuildHeap() {
+	q.heap = q.heap[:0]
+	for i := 0; i < q.length; i++ {
+		e := &q.rep[i]
+		q.heap = append(q.heap, pqHeapEntry{idx: i, priority: e.Priority, seq: e.seq})
+	}
+	for i := len(q.heap)/2 - 1; i >= 0; i-- {
+		q.siftDown(i)
+	}
+}
+
+func (q *PriorityQueue[T]) pushHeap(idx, priority int, seq uint64) {
+	q.heap = append(q.heap, pqHeapEntry{idx: idx, priority: priority, seq: seq})
+	q.siftUp(len(q.heap) - 1)
+}
+
+func (q *PriorityQueue[T]) popHeapRoot() pqHeapEntry {
+	root := q.heap[0]
+	last := len(q.heap) - 1
+	q.heap[0] = q.heap[last]
+	q.heap = q.heap[:last]
+	if len(q.heap) > 0 {
+		q.siftDown(0)
+	}
+	return root
+}
+
+// higher reports whether heap entry a should come out of PopHighest before
+// heap entry b: greater priority wins, and equal priority falls back to
+// insertion order (lower seq, i.e. FIFO).
+func (q *PriorityQueue[T]) higher(a, b pqHeapEntry) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (q *PriorityQueue[T]) siftUp(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !q.higher(q.heap[i], q.heap[parent]) {
+			break
+		}
+		q.heap[parent], q.heap[i] = q.heap[i], q.heap[parent]
+		i = parent
+	}
+}
+
+func (q *PriorityQueue[T]) siftDown(i int) {
+	n := len(q.heap)
+	for {
+		left, right, largest := 2*i+1, 2*i+2, i
+		if left < n && q.higher(q.heap[left], q.heap[largest]) {
+			largest = left
+		}
+		if right < n && q.higher(q.heap[right], q.heap[largest]) {
+			largest = right
+		}
+		if largest == i {
+			return
+		}
+		q.heap[i], q.heap[largest] = q.heap[largest], q.heap[i]
+		i = largest
+	}
+}
+
+func (q *PriorityQueue[T]) push(v T, priority int, front bool) {
+	q.lazyInit()
+	q.lazyGrow()
+	var idx int
+	if front {
+		q.front = q.dec(q.front)
+		idx = q.front
+	} else {
+		idx = q.back
+		q.back = q.inc(q.back)
+	}
+	q.rep[idx] = pqEntry[T]{Value: v, Priority: priority, seq: q.nextSeq}
+	q.pushHeap(idx, priority, q.nextSeq)
+	q.nextSeq++
+	q.length++
+	q.occupied++
+}
+
+// PushBack inserts v at the back of queue q with priority 0.
+func (q *PriorityQueue[T]) PushBack(v T) {
+	q.push(v, 0, false)
+}
+
+// PushFront inserts v at the front of queue q with priority 0.
+func (q *PriorityQueue[T]) PushFront(v T) {
+	q.push(v, 0, true)
+}
+
+// PushWithPriority inserts v at the back of queue q with priority p.
+// PopHighest returns entries in descending order of p, regardless of push
+// order; ties break in FIFO order among pushes of equal priority.
+func (q *PriorityQueue[T]) PushWithPriority(v T, p int) {
+	q.push(v, p, false)
+}
+
+// popFrontLive skips and reclaims any leading tombstones left behind by
+// PopHighest, then removes and returns the first live element.
+func (q *PriorityQueue[T]) popFrontLive() (T, bool) {
+	for q.occupied > 0 && q.rep[q.front].tomb {
+		q.rep[q.front] = pqEntry[T]{}
+		q.front = q.inc(q.front)
+		q.occupied--
+	}
+	if q.length == 0 {
+		return *new(T), false
+	}
+	v := q.rep[q.front].Value
+	// Mark tomb rather than fully clearing: a not-yet-popped heap entry
+	// may still point at this slot, and must see it as stale.
+	q.rep[q.front] = pqEntry[T]{tomb: true}
+	q.front = q.inc(q.front)
+	q.length--
+	q.occupied--
+	q.lazyShrink()
+	return v, true
+}
+
+// popBackLive skips and reclaims any trailing tombstones left behind by
+// PopHighest, then removes and returns the last live element.
+func (q *PriorityQueue[T]) popBackLive() (T, bool) {
+	for q.occupied > 0 && q.rep[q.dec(q.back)].tomb {
+		q.back = q.dec(q.back)
+		q.rep[q.back] = pqEntry[T]{}
+		q.occupied--
+	}
+	if q.length == 0 {
+		return *new(T), false
+	}
+	q.back = q.dec(q.back)
+	v := q.rep[q.back].Value
+	// Mark tomb rather than fully clearing: a not-yet-popped heap entry
+	// may still point at this slot, and must see it as stale.
+	q.rep[q.back] = pqEntry[T]{tomb: true}
+	q.length--
+	q.occupied--
+	q.lazyShrink()
+	return v, true
+}
+
+// PopFront removes and returns the first live element of queue q in FIFO
+// order, or T's zero value if q has no live elements.
+func (q *PriorityQueue[T]) PopFront() (T, bool) {
+	return q.popFrontLive()
+}
+
+// PopBack removes and returns the last live element of queue q in FIFO
+// order, or T's zero value if q has no live elements.
+func (q *PriorityQueue[T]) PopBack() (T, bool) {
+	return q.popBackLive()
+}
+
+// PopHighest removes and returns the live element of queue q with the
+// greatest priority, or T's zero value if q has no live elements. The slot
+// it occupied is left as a tombstone; it is reclaimed the next time
+// PopFront/PopBack walks past it, or on the next resize.
+func (q *PriorityQueue[T]) PopHighest() (T, bool) {
+	for len(q.heap) > 0 {
+		e := q.popHeapRoot()
+		slot := &q.rep[e.idx]
+		if slot.tomb || slot.seq != e.seq {
+			// Stale: either PopFront/PopBack tombstoned this slot directly,
+			// or it has since been reused by a later push entirely (its
+			// seq no longer matches the one this heap entry was built
+			// with), so it must not be confused with that new occupant.
+			continue
+		}
+		v := slot.Value
+		*slot = pqEntry[T]{tomb: true}
+		q.length--
+		q.lazyShrink()
+		return v, true
+	}
+	return *new(T), false
+}
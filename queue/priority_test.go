@@ -0,0 +1,113 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import "testing"
+
+func TestPriorityQueueFIFO(t *testing.T) {
+	pq := NewPriorityQueue[int]()
+	for i := 0; i < 10; i++ {
+		pq.PushBack(i)
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := pq.PopFront(); !ok || v != i {
+			t.Fatalf("PopFront = %v, %v; want %d", v, ok, i)
+		}
+	}
+	if _, ok := pq.PopFront(); ok {
+		t.Fatalf("PopFront on empty queue should report false")
+	}
+}
+
+func TestPriorityQueuePopHighestOrder(t *testing.T) {
+	pq := NewPriorityQueue[string]()
+	pq.PushWithPriority("low", 1)
+	pq.PushWithPriority("high", 10)
+	pq.PushWithPriority("mid", 5)
+
+	for _, want := range []string{"high", "mid", "low"} {
+		v, ok := pq.PopHighest()
+		if !ok || v != want {
+			t.Fatalf("PopHighest = %v, %v; want %q", v, ok, want)
+		}
+	}
+	if _, ok := pq.PopHighest(); ok {
+		t.Fatalf("PopHighest on empty queue should report false")
+	}
+}
+
+func TestPriorityQueuePopHighestTiesAreFIFO(t *testing.T) {
+	pq := NewPriorityQueue[int]()
+	for i := 0; i < 20; i++ {
+		pq.PushWithPriority(i, 1)
+	}
+	for i := 0; i < 20; i++ {
+		if v, ok := pq.PopHighest(); !ok || v != i {
+			t.Fatalf("PopHighest[%d] = %v, %v; want %d", i, v, ok, i)
+		}
+	}
+}
+
+func TestPriorityQueueMixedFIFOAndPriority(t *testing.T) {
+	pq := NewPriorityQueue[string]()
+	pq.PushBack("a") // priority 0
+	pq.PushBack("b") // priority 0
+	pq.PushWithPriority("urgent", 100)
+
+	if v, ok := pq.PopHighest(); !ok || v != "urgent" {
+		t.Fatalf("PopHighest = %v, %v; want urgent", v, ok)
+	}
+	// "urgent" is gone; "a" and "b" remain in FIFO order ahead of its
+	// tombstone at the back.
+	if v, ok := pq.PopFront(); !ok || v != "a" {
+		t.Fatalf("PopFront = %v, %v; want a", v, ok)
+	}
+	if v, ok := pq.PopFront(); !ok || v != "b" {
+		t.Fatalf("PopFront = %v, %v; want b", v, ok)
+	}
+}
+
+func TestPriorityQueueTombstoneSurvivesResize(t *testing.T) {
+	pq := NewPriorityQueue[int]()
+	const n = 64
+	for i := 0; i < n; i++ {
+		pq.PushWithPriority(i, i)
+	}
+	// Pop the top half via the heap, leaving a run of tombstones after the
+	// remaining live entries, then push/pop enough to force several
+	// resizes.
+	for i := n - 1; i >= n/2; i-- {
+		if v, ok := pq.PopHighest(); !ok || v != i {
+			t.Fatalf("PopHighest = %v, %v; want %d", v, ok, i)
+		}
+	}
+	if pq.Len() != n/2 {
+		t.Fatalf("Len = %d, want %d", pq.Len(), n/2)
+	}
+	for i := 0; i < n; i++ {
+		pq.PushBack(1000 + i)
+	}
+	for i := 0; i < n/2+n; i++ {
+		if _, ok := pq.PopFront(); !ok {
+			t.Fatalf("PopFront %d: expected a live element", i)
+		}
+	}
+	if _, ok := pq.PopFront(); ok {
+		t.Fatalf("queue should be empty after draining every live element")
+	}
+}
+
+func TestPriorityQueuePopBack(t *testing.T) {
+	pq := NewPriorityQueue[int]()
+	for i := 0; i < 5; i++ {
+		pq.PushBack(i)
+	}
+	if v, ok := pq.PopBack(); !ok || v != 4 {
+		t.Fatalf("PopBack = %v, %v; want 4", v, ok)
+	}
+	if pq.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", pq.Len())
+	}
+}
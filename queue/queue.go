@@ -5,14 +5,40 @@
 // Package queue implements a double-ended queue (aka "deque") data structure
 // on top of a slice. All operations run in (amortized) constant time.
 // Benchmarks compare favorably to container/list as well as to Go's channels.
-// These queues are not safe for concurrent use.
+// The exceptions are Index, RIndex, Insert, Remove, Grow, SetMinCapacity
+// and Clear, which take time linear in the queue's length or capacity.
+// Queue[T] itself is not safe for concurrent use; see ConcurrentQueue for a
+// wrapper that is.
 package queue
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 )
 
+// ErrQueueFull is returned by TryPushBack/TryPushFront when a bounded queue
+// has reached its maximum length and rejects the push. PushBack/PushFront
+// never return it: they report a rejection as false under every policy.
+var ErrQueueFull = errors.New("queue: queue is full")
+
+// OverflowPolicy determines what a bounded Queue[T] does when a push would
+// exceed its maximum length. See NewBounded.
+type OverflowPolicy int
+
+const (
+	// DropNewest rejects the incoming value: PushBack/PushFront leave the
+	// queue unchanged and return false.
+	DropNewest OverflowPolicy = iota
+	// DropOldest evicts the element at the opposite end before inserting,
+	// so PushBack/PushFront always succeed.
+	DropOldest
+	// ReturnError rejects the incoming value exactly as DropNewest does:
+	// PushBack/PushFront return false. TryPushBack/TryPushFront report the
+	// rejection as ErrQueueFull, under this policy and DropNewest alike.
+	ReturnError
+)
+
 type entry[T any] struct {
 	Value    T
 	ValueSet bool
@@ -28,6 +54,18 @@ type Queue[T any] struct {
 	front  int
 	back   int
 	length int
+
+	// maxLen is the logical capacity of a bounded queue, or 0 if the
+	// queue is unbounded. capLimit is the smallest power of two that is
+	// >= maxLen, and caps how far lazyGrow will let rep grow.
+	maxLen   int
+	capLimit int
+	policy   OverflowPolicy
+
+	// minCap is the floor, in elements, below which lazyShrink will not
+	// shrink rep. It defaults to 0, which sets no floor beyond sparse's
+	// own conditions.
+	minCap int
 }
 
 // New returns an initialized empty queue.
@@ -35,9 +73,48 @@ func New[T any]() *Queue[T] {
 	return new(Queue[T]).Init()
 }
 
-// Init initializes or clears queue q.
+// NewBounded returns an initialized empty queue that holds at most max
+// elements, applying policy whenever a push would exceed that limit.
+func NewBounded[T any](max int, policy OverflowPolicy) *Queue[T] {
+	q := New[T]()
+	q.policy = policy
+	q.SetMaxLen(max)
+	return q
+}
+
+// SetMaxLen sets the logical capacity of queue q to n, turning it into a
+// bounded queue. A non-positive n makes q unbounded again.
+func (q *Queue[T]) SetMaxLen(n int) {
+	if n <= 0 {
+		q.maxLen, q.capLimit = 0, 0
+		return
+	}
+	q.maxLen = n
+	q.capLimit = nextPow2(n)
+}
+
+// nextPow2 returns the smallest power of two that is >= n.
+func nextPow2(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+// boundedFull reports whether queue q has reached its logical capacity.
+func (q *Queue[T]) boundedFull() bool {
+	return q.maxLen > 0 && q.length >= q.maxLen
+}
+
+// Init initializes or clears queue q, honoring any floor set by
+// SetMinCapacity rather than dropping rep back to its absolute minimum.
 func (q *Queue[T]) Init() *Queue[T] {
-	q.rep = make([]entry[T], 1)
+	size := 1
+	if q.minCap > size {
+		size = q.minCap
+	}
+	q.rep = make([]entry[T], size)
 	q.front, q.back, q.length = 0, 0, 0
 	return q
 }
@@ -71,7 +148,47 @@ func (q *Queue[T]) full() bool {
 
 // sparse returns true if the queue q has excess capacity.
 func (q *Queue[T]) sparse() bool {
-	return 1 < q.length && q.length < len(q.rep)/4
+	return 1 < q.length && q.length < len(q.rep)/4 && len(q.rep)/2 >= q.minCap
+}
+
+// Grow ensures that rep has room for at least n additional elements
+// without lazyGrow triggering a resize on the next n pushes. On a bounded
+// queue, rep never grows past capLimit regardless of n, matching lazyGrow.
+func (q *Queue[T]) Grow(n int) {
+	q.lazyInit()
+	if n <= 0 {
+		return
+	}
+	size := nextPow2(q.length + n)
+	if q.capLimit > 0 && size > q.capLimit {
+		size = q.capLimit
+	}
+	if size > len(q.rep) {
+		q.resize(size)
+	}
+}
+
+// SetMinCapacity pins the floor of queue q's underlying slice to
+// 1<<exponent elements: lazyShrink will never resize rep below that size.
+func (q *Queue[T]) SetMinCapacity(exponent uint) {
+	q.minCap = 1 << exponent
+	q.lazyInit()
+	if len(q.rep) < q.minCap {
+		q.resize(q.minCap)
+	}
+}
+
+// Clear removes all elements from queue q in place, without reallocating
+// rep. Existing entries are zeroed out just as PopFront/PopBack do, so
+// their memory can be garbage collected.
+func (q *Queue[T]) Clear() {
+	if q.rep == nil {
+		return
+	}
+	for i := range q.rep {
+		q.rep[i] = entry[T]{}
+	}
+	q.front, q.back, q.length = 0, 0, 0
 }
 
 // resize adjusts the size of queue q's underlying slice.
@@ -90,9 +207,15 @@ func (q *Queue[T]) resize(size int) {
 	q.back = q.length
 }
 
-// lazyGrow grows the underlying slice if necessary.
+// lazyGrow grows the underlying slice if necessary. For a bounded queue,
+// growth stops once rep has reached capLimit even if q.full() is true;
+// boundedFull (checked by the callers that enforce maxLen) keeps the
+// queue from overflowing that final power-of-two slice.
 func (q *Queue[T]) lazyGrow() {
 	if q.full() {
+		if q.capLimit > 0 && len(q.rep) >= q.capLimit {
+			return
+		}
 		q.resize(len(q.rep) * 2)
 	}
 }
@@ -147,22 +270,84 @@ func (q *Queue[T]) Back() (T, bool) {
 	return q.rep[q.dec(q.back)].Value, true
 }
 
-// PushFront inserts a new value v at the front of queue q.
-func (q *Queue[T]) PushFront(v T) {
+// PushFront inserts a new value v at the front of queue q. It returns false
+// without modifying q if q is bounded, already full, and the overflow
+// policy is DropNewest or ReturnError; use TryPushFront to distinguish the
+// latter case, or NewBounded with DropOldest to always succeed.
+func (q *Queue[T]) PushFront(v T) bool {
 	q.lazyInit()
+	if q.boundedFull() {
+		if q.policy == DropOldest {
+			q.PopBack()
+		} else {
+			return false
+		}
+	}
 	q.lazyGrow()
 	q.front = q.dec(q.front)
 	q.rep[q.front] = entry[T]{Value: v, ValueSet: true}
 	q.length++
+	return true
 }
 
-// PushBack inserts a new value v at the back of queue q.
-func (q *Queue[T]) PushBack(v T) {
+// PushBack inserts a new value v at the back of queue q. It returns false
+// without modifying q if q is bounded, already full, and the overflow
+// policy is DropNewest or ReturnError; use TryPushBack to distinguish the
+// latter case, or NewBounded with DropOldest to always succeed.
+func (q *Queue[T]) PushBack(v T) bool {
 	q.lazyInit()
+	if q.boundedFull() {
+		if q.policy == DropOldest {
+			q.PopFront()
+		} else {
+			return false
+		}
+	}
 	q.lazyGrow()
 	q.rep[q.back] = entry[T]{Value: v, ValueSet: true}
 	q.back = q.inc(q.back)
 	q.length++
+	return true
+}
+
+// TryPushFront behaves like PushFront, but reports rejection as
+// ErrQueueFull instead of a bare false, matching the ReturnError policy.
+func (q *Queue[T]) TryPushFront(v T) error {
+	if !q.PushFront(v) {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// TryPushBack behaves like PushBack, but reports rejection as
+// ErrQueueFull instead of a bare false, matching the ReturnError policy.
+func (q *Queue[T]) TryPushBack(v T) error {
+	if !q.PushBack(v) {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// PushFrontEvict inserts v at the front of queue q, evicting and returning
+// the back element first if q is already at its maxLen, regardless of q's
+// configured policy. It reports whether an element was evicted.
+func (q *Queue[T]) PushFrontEvict(v T) (evicted T, evictedOK bool) {
+	if q.boundedFull() {
+		evicted, evictedOK = q.PopBack()
+	}
+	q.PushFront(v)
+	return evicted, evictedOK
+}
+
+// PushBackEvict inserts v at the back of queue q, evicting and returning
+// the front element first if q is already at its maxLen, regardless of q's
+// configured policy. It reports whether an element was evicted.
+func (q *Queue[T]) PushBackEvict(v T) (evicted T, evictedOK bool) {
+	if q.boundedFull() {
+		evicted, evictedOK = q.PopFront()
+	}
+	q.PushBack(v)
+	return evicted, evictedOK
 }
 
 // PopFront removes and returns the first element of queue q or T's zero value.
@@ -190,3 +375,134 @@ func (q *Queue[T]) PopBack() (T, bool) {
 	q.lazyShrink()
 	return v.Value, true
 }
+
+// At returns the element at logical index i (0 is the front of queue q) or
+// T's zero value if i is out of range.
+func (q *Queue[T]) At(i int) (T, bool) {
+	if i < 0 || i >= q.length {
+		return *new(T), false
+	}
+	return q.rep[(q.front+i)&(len(q.rep)-1)].Value, true
+}
+
+// Set overwrites the element at logical index i (0 is the front of queue q)
+// with v. It reports whether i was in range.
+func (q *Queue[T]) Set(i int, v T) bool {
+	if i < 0 || i >= q.length {
+		return false
+	}
+	q.rep[(q.front+i)&(len(q.rep)-1)].Value = v
+	return true
+}
+
+// Index returns the logical index of the first element for which match
+// returns true, scanning from front to back, or -1 if no element matches.
+func (q *Queue[T]) Index(match func(T) bool) int {
+	j := q.front
+	for i := 0; i < q.length; i++ {
+		if match(q.rep[j].Value) {
+			return i
+		}
+		j = q.inc(j)
+	}
+	return -1
+}
+
+// RIndex returns the logical index of the last element for which match
+// returns true, scanning from back to front, or -1 if no element matches.
+func (q *Queue[T]) RIndex(match func(T) bool) int {
+	j := q.dec(q.back)
+	for i := q.length - 1; i >= 0; i-- {
+		if match(q.rep[j].Value) {
+			return i
+		}
+		j = q.dec(j)
+	}
+	return -1
+}
+
+// Insert inserts v at logical index i, first growing the underlying slice
+// via lazyGrow if necessary, then shifting whichever of the front or back
+// half is shorter to make room. It panics if i is out of the range [0, q.Len()].
+// If q is bounded and already full, Insert applies the same overflow
+// policy as PushBack/PushFront: it returns false without modifying q under
+// DropNewest/ReturnError, or evicts the front (oldest) element under
+// DropOldest before inserting.
+func (q *Queue[T]) Insert(i int, v T) bool {
+	if i < 0 || i > q.length {
+		panic("queue: Insert index out of range")
+	}
+	if q.boundedFull() {
+		if q.policy != DropOldest {
+			return false
+		}
+		q.PopFront()
+		if i > 0 {
+			i-- // every remaining element shifted down by one
+		}
+	}
+	if i == 0 {
+		return q.PushFront(v)
+	}
+	if i == q.length {
+		return q.PushBack(v)
+	}
+	q.lazyInit()
+	q.lazyGrow()
+	mask := len(q.rep) - 1
+	if i <= q.length-i {
+		// Shift the front half back by one to open a gap at i.
+		newFront := q.dec(q.front)
+		for k := 0; k < i; k++ {
+			src := (q.front + k) & mask
+			dst := (newFront + k) & mask
+			q.rep[dst] = q.rep[src]
+		}
+		q.front = newFront
+		q.rep[(newFront+i)&mask] = entry[T]{Value: v, ValueSet: true}
+	} else {
+		// Shift the back half forward by one to open a gap at i.
+		for k := q.length; k > i; k-- {
+			src := (q.front + k - 1) & mask
+			dst := (q.front + k) & mask
+			q.rep[dst] = q.rep[src]
+		}
+		q.rep[(q.front+i)&mask] = entry[T]{Value: v, ValueSet: true}
+		q.back = q.inc(q.back)
+	}
+	q.length++
+	return true
+}
+
+// Remove removes and returns the element at logical index i, shifting
+// whichever of the front or back half is shorter to close the gap. It
+// panics if i is out of the range [0, q.Len()).
+func (q *Queue[T]) Remove(i int) T {
+	if i < 0 || i >= q.length {
+		panic("queue: Remove index out of range")
+	}
+	mask := len(q.rep) - 1
+	removed := q.rep[(q.front+i)&mask].Value
+	if i <= q.length-1-i {
+		// Shift the front half forward by one to close the gap.
+		for k := i; k > 0; k-- {
+			dst := (q.front + k) & mask
+			src := (q.front + k - 1) & mask
+			q.rep[dst] = q.rep[src]
+		}
+		q.rep[q.front] = entry[T]{ValueSet: false} // unused slots must be set to empty
+		q.front = q.inc(q.front)
+	} else {
+		// Shift the back half back by one to close the gap.
+		for k := i; k < q.length-1; k++ {
+			dst := (q.front + k) & mask
+			src := (q.front + k + 1) & mask
+			q.rep[dst] = q.rep[src]
+		}
+		q.back = q.dec(q.back)
+		q.rep[q.back] = entry[T]{ValueSet: false} // unused slots must be set to empty
+	}
+	q.length--
+	q.lazyShrink()
+	return removed
+}
@@ -0,0 +1,386 @@
+// Copyright (c) 2013-2017, Peter H. Froehlich. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package queue
+
+import "testing"
+
+func TestQueueAtSet(t *testing.T) {
+	q := New[int]()
+	for i := 0; i < 5; i++ {
+		q.PushBack(i)
+	}
+	for i := 0; i < 5; i++ {
+		if v, ok := q.At(i); !ok || v != i {
+			t.Fatalf("At(%d) = %v, %v; want %d", i, v, ok, i)
+		}
+	}
+	if _, ok := q.At(-1); ok {
+		t.Fatalf("At(-1) should report false")
+	}
+	if _, ok := q.At(5); ok {
+		t.Fatalf("At(5) should report false")
+	}
+	if !q.Set(2, 200) {
+		t.Fatalf("Set(2, ...) should report true")
+	}
+	if v, _ := q.At(2); v != 200 {
+		t.Fatalf("At(2) = %d, want 200 after Set", v)
+	}
+	if q.Set(5, 0) {
+		t.Fatalf("Set(5, ...) should report false")
+	}
+}
+
+func TestQueueIndexRIndex(t *testing.T) {
+	q := New[int]()
+	for _, v := range []int{10, 20, 30, 20, 10} {
+		q.PushBack(v)
+	}
+	if i := q.Index(func(v int) bool { return v == 20 }); i != 1 {
+		t.Fatalf("Index(==20) = %d, want 1", i)
+	}
+	if i := q.RIndex(func(v int) bool { return v == 20 }); i != 3 {
+		t.Fatalf("RIndex(==20) = %d, want 3", i)
+	}
+	if i := q.Index(func(v int) bool { return v == 99 }); i != -1 {
+		t.Fatalf("Index(==99) = %d, want -1", i)
+	}
+}
+
+// TestQueueInsertShiftsShorterHalf exercises both branches of Insert's
+// front/back shift, including inserting through the wrap point.
+func TestQueueInsertShiftsShorterHalf(t *testing.T) {
+	q := New[int]()
+	for i := 0; i < 8; i++ {
+		q.PushBack(i)
+	}
+	// Force front != 0 so later pushes wrap around the end of rep.
+	for i := 0; i < 3; i++ {
+		q.PopFront()
+	}
+	// [3 4 5 6 7], length 5
+
+	if !q.Insert(0, -1) { // PushFront fast path
+		t.Fatalf("Insert(0, ...) = false")
+	}
+	if !q.Insert(q.Len(), 100) { // PushBack fast path
+		t.Fatalf("Insert(Len, ...) = false")
+	}
+	if !q.Insert(1, 999) { // i <= length-i: shift the front half
+		t.Fatalf("Insert(1, ...) = false")
+	}
+	if !q.Insert(q.Len()-1, 888) { // i > length-i: shift the back half
+		t.Fatalf("Insert(Len-1, ...) = false")
+	}
+
+	want := []int{-1, 999, 3, 4, 5, 6, 7, 888, 100}
+	if q.Len() != len(want) {
+		t.Fatalf("Len = %d, want %d", q.Len(), len(want))
+	}
+	for i, w := range want {
+		if v, ok := q.At(i); !ok || v != w {
+			t.Fatalf("At(%d) = %v, %v; want %d", i, v, ok, w)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Insert out of range should panic")
+		}
+	}()
+	q.Insert(q.Len()+1, 0)
+}
+
+// TestQueueRemoveShiftsShorterHalf exercises both branches of Remove's
+// gap-closing shift, including removing through the wrap point.
+func TestQueueRemoveShiftsShorterHalf(t *testing.T) {
+	q := New[int]()
+	for i := 0; i < 8; i++ {
+		q.PushBack(i)
+	}
+	for i := 0; i < 3; i++ {
+		q.PopFront()
+	}
+	// [3 4 5 6 7], length 5
+
+	if v := q.Remove(0); v != 3 { // shifts the (empty) front half
+		t.Fatalf("Remove(0) = %d, want 3", v)
+	}
+	if v := q.Remove(q.Len() - 1); v != 7 { // shifts the (empty) back half
+		t.Fatalf("Remove(Len-1) = %d, want 7", v)
+	}
+	// [4 5 6]
+	if v := q.Remove(1); v != 5 { // i <= length-1-i: shift the front half
+		t.Fatalf("Remove(1) = %d, want 5", v)
+	}
+	// [4 6]
+	if v := q.Remove(1); v != 6 { // i > length-1-i: shift the back half
+		t.Fatalf("Remove(1) = %d, want 6", v)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", q.Len())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Remove out of range should panic")
+		}
+	}()
+	q.Remove(5)
+}
+
+func TestQueueBoundedDropNewest(t *testing.T) {
+	q := NewBounded[int](3, DropNewest)
+	for i := 0; i < 3; i++ {
+		if !q.PushBack(i) {
+			t.Fatalf("PushBack(%d) = false, want true", i)
+		}
+	}
+	if q.PushBack(3) {
+		t.Fatalf("PushBack on a full DropNewest queue should return false")
+	}
+	if q.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", q.Len())
+	}
+	if v, _ := q.Back(); v != 2 {
+		t.Fatalf("Back = %d, want 2; newest push should have been dropped", v)
+	}
+}
+
+func TestQueueBoundedDropOldest(t *testing.T) {
+	q := NewBounded[int](3, DropOldest)
+	for i := 0; i < 3; i++ {
+		q.PushBack(i)
+	}
+	if !q.PushBack(3) {
+		t.Fatalf("PushBack on a full DropOldest queue should always succeed")
+	}
+	if q.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", q.Len())
+	}
+	if v, _ := q.Front(); v != 1 {
+		t.Fatalf("Front = %d, want 1; oldest element should have been evicted", v)
+	}
+}
+
+func TestQueueBoundedReturnError(t *testing.T) {
+	q := NewBounded[int](2, ReturnError)
+	if err := q.TryPushBack(1); err != nil {
+		t.Fatalf("TryPushBack(1) = %v, want nil", err)
+	}
+	if err := q.TryPushBack(2); err != nil {
+		t.Fatalf("TryPushBack(2) = %v, want nil", err)
+	}
+	if err := q.TryPushBack(3); err != ErrQueueFull {
+		t.Fatalf("TryPushBack(3) = %v, want ErrQueueFull", err)
+	}
+	if err := q.TryPushFront(3); err != ErrQueueFull {
+		t.Fatalf("TryPushFront(3) = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestQueuePushEvict(t *testing.T) {
+	q := NewBounded[int](2, DropNewest)
+	q.PushBack(1)
+	q.PushBack(2)
+
+	evicted, ok := q.PushBackEvict(3)
+	if !ok || evicted != 1 {
+		t.Fatalf("PushBackEvict = %v, %v; want 1, true", evicted, ok)
+	}
+	if v, _ := q.Back(); v != 3 {
+		t.Fatalf("Back = %d, want 3", v)
+	}
+
+	evicted, ok = q.PushFrontEvict(4)
+	if !ok || evicted != 3 {
+		t.Fatalf("PushFrontEvict = %v, %v; want 3, true", evicted, ok)
+	}
+	if v, _ := q.Front(); v != 4 {
+		t.Fatalf("Front = %d, want 4", v)
+	}
+
+	u := New[int]()
+	if _, ok := u.PushBackEvict(1); ok {
+		t.Fatalf("PushBackEvict on an unbounded queue should never report an eviction")
+	}
+}
+
+func TestQueueAllBackward(t *testing.T) {
+	q := New[int]()
+	for i := 0; i < 5; i++ {
+		q.PushBack(i)
+	}
+
+	var gotIdx, gotVal []int
+	for i, v := range q.All() {
+		gotIdx = append(gotIdx, i)
+		gotVal = append(gotVal, v)
+	}
+	wantIdx := []int{0, 1, 2, 3, 4}
+	wantVal := []int{0, 1, 2, 3, 4}
+	if !slicesEqual(gotIdx, wantIdx) || !slicesEqual(gotVal, wantVal) {
+		t.Fatalf("All() = %v, %v; want %v, %v", gotIdx, gotVal, wantIdx, wantVal)
+	}
+
+	gotIdx, gotVal = nil, nil
+	for i, v := range q.Backward() {
+		gotIdx = append(gotIdx, i)
+		gotVal = append(gotVal, v)
+	}
+	wantIdx = []int{4, 3, 2, 1, 0}
+	wantVal = []int{4, 3, 2, 1, 0}
+	if !slicesEqual(gotIdx, wantIdx) || !slicesEqual(gotVal, wantVal) {
+		t.Fatalf("Backward() = %v, %v; want %v, %v", gotIdx, gotVal, wantIdx, wantVal)
+	}
+
+	var stopped []int
+	for i, v := range q.All() {
+		if i == 2 {
+			break
+		}
+		stopped = append(stopped, v)
+	}
+	if !slicesEqual(stopped, []int{0, 1}) {
+		t.Fatalf("All() did not stop early: got %v", stopped)
+	}
+}
+
+func TestQueueRangeRangeReverse(t *testing.T) {
+	q := New[int]()
+	for i := 0; i < 5; i++ {
+		q.PushBack(i)
+	}
+
+	var got []int
+	q.Range(func(i, v int) bool {
+		got = append(got, v)
+		return true
+	})
+	if !slicesEqual(got, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("Range() visited %v, want 0..4", got)
+	}
+
+	got = nil
+	q.RangeReverse(func(i, v int) bool {
+		got = append(got, v)
+		return true
+	})
+	if !slicesEqual(got, []int{4, 3, 2, 1, 0}) {
+		t.Fatalf("RangeReverse() visited %v, want 4..0", got)
+	}
+
+	got = nil
+	q.Range(func(i, v int) bool {
+		got = append(got, v)
+		return v < 2
+	})
+	if !slicesEqual(got, []int{0, 1, 2}) {
+		t.Fatalf("Range() did not stop when f returned false: got %v", got)
+	}
+}
+
+func slicesEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQueueGrow(t *testing.T) {
+	q := New[int]()
+	q.PushBack(1)
+	before := len(q.rep)
+	q.Grow(64)
+	if got := len(q.rep); got < 65 {
+		t.Fatalf("len(rep) after Grow(64) = %d, want at least 65", got)
+	}
+	if got := len(q.rep); got == before {
+		t.Fatalf("Grow(64) did not resize rep")
+	}
+	// Growing for room already available must not shrink or reallocate.
+	grown := len(q.rep)
+	q.Grow(1)
+	if len(q.rep) != grown {
+		t.Fatalf("len(rep) = %d after a no-op Grow, want %d", len(q.rep), grown)
+	}
+}
+
+func TestQueueGrowClampsToCapLimit(t *testing.T) {
+	q := NewBounded[int](3, DropNewest)
+	q.Grow(1000)
+	if got := len(q.rep); got != q.capLimit {
+		t.Fatalf("len(rep) after Grow(1000) on a bounded(3) queue = %d, want capLimit %d", got, q.capLimit)
+	}
+}
+
+func TestQueueSetMinCapacity(t *testing.T) {
+	q := New[int]()
+	q.SetMinCapacity(4) // floor of 1<<4 = 16
+	if got := len(q.rep); got != 16 {
+		t.Fatalf("len(rep) after SetMinCapacity(4) = %d, want 16", got)
+	}
+	for i := 0; i < 100; i++ {
+		q.PushBack(i)
+	}
+	for i := 0; i < 98; i++ {
+		q.PopFront()
+	}
+	if got := len(q.rep); got < 16 {
+		t.Fatalf("len(rep) = %d after draining below the floor, want at least 16", got)
+	}
+}
+
+func TestQueueInitHonorsMinCapacity(t *testing.T) {
+	q := New[int]()
+	q.SetMinCapacity(4) // floor of 1<<4 = 16
+	q.PushBack(1)
+	q.Init()
+	if got := len(q.rep); got != 16 {
+		t.Fatalf("len(rep) after Init() = %d, want 16 (the SetMinCapacity floor)", got)
+	}
+}
+
+func TestQueueClear(t *testing.T) {
+	q := New[int]()
+	for i := 0; i < 10; i++ {
+		q.PushBack(i)
+	}
+	repBefore := len(q.rep)
+	q.Clear()
+	if q.Len() != 0 {
+		t.Fatalf("Len = %d after Clear, want 0", q.Len())
+	}
+	if len(q.rep) != repBefore {
+		t.Fatalf("len(rep) = %d after Clear, want %d (Clear must not reallocate)", len(q.rep), repBefore)
+	}
+	if _, ok := q.Front(); ok {
+		t.Fatalf("Front should report false on a cleared queue")
+	}
+	q.PushBack(99)
+	if v, ok := q.Front(); !ok || v != 99 {
+		t.Fatalf("Front = %v, %v after pushing into a cleared queue; want 99, true", v, ok)
+	}
+}
+
+func TestQueueSetMaxLenUnbounds(t *testing.T) {
+	q := NewBounded[int](2, DropNewest)
+	q.PushBack(1)
+	q.PushBack(2)
+	q.SetMaxLen(0)
+	for i := 3; i < 10; i++ {
+		if !q.PushBack(i) {
+			t.Fatalf("PushBack(%d) = false after SetMaxLen(0) lifted the bound", i)
+		}
+	}
+	if q.Len() != 9 {
+		t.Fatalf("Len = %d, want 9", q.Len())
+	}
+}